@@ -0,0 +1,168 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// chainMember is a MetaValidator used to drive ChainValidator tests.
+type chainMember struct {
+	err error
+}
+
+func (c *chainMember) DeepCopyObject() runtime.Object                          { return &decodableObject{} }
+func (c *chainMember) ValidateCreate(runtime.Object, AdmissionRequest) error    { return c.err }
+func (c *chainMember) ValidateDelete(runtime.Object, AdmissionRequest) error    { return c.err }
+func (c *chainMember) ValidateUpdate(_, _ runtime.Object, _ AdmissionRequest) error {
+	return c.err
+}
+
+var _ MetaValidator = &chainMember{}
+
+// chainMemberWithWarnings is a MetaValidatorWithWarnings used to drive
+// ChainValidator tests.
+type chainMemberWithWarnings struct {
+	warnings []string
+	err      error
+}
+
+func (c *chainMemberWithWarnings) DeepCopyObject() runtime.Object { return &decodableObject{} }
+func (c *chainMemberWithWarnings) ValidateCreate(runtime.Object, AdmissionRequest) ([]string, error) {
+	return c.warnings, c.err
+}
+func (c *chainMemberWithWarnings) ValidateDelete(runtime.Object, AdmissionRequest) ([]string, error) {
+	return c.warnings, c.err
+}
+func (c *chainMemberWithWarnings) ValidateUpdate(_, _ runtime.Object, _ AdmissionRequest) ([]string, error) {
+	return c.warnings, c.err
+}
+
+var _ MetaValidatorWithWarnings = &chainMemberWithWarnings{}
+
+func TestChainValidatorFailFastStopsAtFirstDenial(t *testing.T) {
+	calls := 0
+	counting := &countingValidator{calls: &calls}
+	c := &ChainValidator{
+		Mode: FailFast,
+		Validators: []ChainableValidator{
+			&chainMember{err: errors.New("first denies")},
+			counting,
+		},
+	}
+
+	_, err := c.ValidateCreate(nil, AdmissionRequest{})
+	if err == nil || err.Error() != "first denies" {
+		t.Fatalf("ValidateCreate() error = %v, want %q", err, "first denies")
+	}
+	if calls != 0 {
+		t.Fatalf("expected the second validator to be skipped, it ran %d times", calls)
+	}
+}
+
+// countingValidator records how many times it was invoked, to assert that
+// FailFast short-circuits the chain.
+type countingValidator struct {
+	calls *int
+}
+
+func (c *countingValidator) DeepCopyObject() runtime.Object { return &decodableObject{} }
+func (c *countingValidator) ValidateCreate(runtime.Object, AdmissionRequest) error {
+	*c.calls++
+	return nil
+}
+func (c *countingValidator) ValidateUpdate(_, _ runtime.Object, _ AdmissionRequest) error {
+	*c.calls++
+	return nil
+}
+func (c *countingValidator) ValidateDelete(runtime.Object, AdmissionRequest) error {
+	*c.calls++
+	return nil
+}
+
+func TestChainValidatorAggregateConcatenatesReasonsAndWarnings(t *testing.T) {
+	c := &ChainValidator{
+		Mode: Aggregate,
+		Validators: []ChainableValidator{
+			&chainMemberWithWarnings{warnings: []string{"w1"}, err: errors.New("denied by first")},
+			&chainMember{err: errors.New("denied by second")},
+			&chainMemberWithWarnings{warnings: []string{"w2"}},
+		},
+	}
+
+	warnings, err := c.ValidateCreate(nil, AdmissionRequest{})
+	if err == nil {
+		t.Fatalf("expected an aggregated denial")
+	}
+	if got, want := err.Error(), "denied by first; denied by second"; got != want {
+		t.Fatalf("error = %q, want %q", got, want)
+	}
+	if got, want := warnings, []string{"w1", "w2"}; !equalStrings(got, want) {
+		t.Fatalf("warnings = %v, want %v", got, want)
+	}
+}
+
+func TestChainValidatorAggregateAllowsWhenNoneDeny(t *testing.T) {
+	c := &ChainValidator{
+		Mode: Aggregate,
+		Validators: []ChainableValidator{
+			&chainMemberWithWarnings{warnings: []string{"w1"}},
+			&chainMember{},
+		},
+	}
+
+	warnings, err := c.ValidateDelete(nil, AdmissionRequest{})
+	if err != nil {
+		t.Fatalf("ValidateDelete() error = %v, want nil", err)
+	}
+	if got, want := warnings, []string{"w1"}; !equalStrings(got, want) {
+		t.Fatalf("warnings = %v, want %v", got, want)
+	}
+}
+
+func TestChainValidatorDeepCopyObjectPanicsWhenEmpty(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected DeepCopyObject to panic on an empty chain")
+		}
+	}()
+	(&ChainValidator{}).DeepCopyObject()
+}
+
+func TestValidatingWebhookForAllPanicsWithNoValidators(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected ValidatingWebhookForAll to panic with no validators")
+		}
+	}()
+	ValidatingWebhookForAll(FailFast)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
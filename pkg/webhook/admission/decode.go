@@ -0,0 +1,51 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Decoder decodes the raw object bytes carried on a Request into a concrete
+// runtime.Object. Kubernetes API objects round-trip through JSON via their
+// standard struct tags, so a plain encoding/json.Unmarshal is sufficient
+// here; Decoder doesn't need a scheme-aware universal deserializer to decode
+// the admission objects this package hands validators.
+type Decoder struct{}
+
+// NewDecoder returns a Decoder. scheme is accepted for forward compatibility
+// with callers that already have one on hand, but is currently unused: see
+// the Decoder doc comment for why a scheme isn't required.
+func NewDecoder(scheme *runtime.Scheme) *Decoder {
+	return &Decoder{}
+}
+
+// Decode unmarshals the object carried by req into obj.
+func (d *Decoder) Decode(req Request, obj runtime.Object) error {
+	return d.DecodeRaw(req.Object, obj)
+}
+
+// DecodeRaw unmarshals rawObj into obj.
+func (d *Decoder) DecodeRaw(rawObj runtime.RawExtension, obj runtime.Object) error {
+	if len(rawObj.Raw) == 0 {
+		return fmt.Errorf("there is no content to decode")
+	}
+	return json.Unmarshal(rawObj.Raw, obj)
+}
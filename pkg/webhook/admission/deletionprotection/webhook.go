@@ -0,0 +1,123 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deletionprotection provides a generic, label/annotation-based
+// delete-protection admission webhook. Unlike a hand-written validator for a
+// single type, the webhook registered by this package can be mounted for any
+// GroupVersionResource by configuring the matching ValidatingWebhookConfiguration
+// rule, since it decodes incoming objects as unstructured.Unstructured.
+package deletionprotection
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Rule describes the label or annotation that protects matching objects from
+// deletion. Set exactly one of Label or Annotation; if both are set, Label
+// takes precedence.
+type Rule struct {
+	// Label is the label key whose value must equal Value for an object to
+	// be protected from deletion.
+	Label string
+	// Annotation is the annotation key whose value must equal Value for an
+	// object to be protected from deletion. Only consulted if Label is unset.
+	Annotation string
+	// Value is the required value of Label or Annotation.
+	Value string
+}
+
+// key returns whichever of Label or Annotation is in effect for this Rule.
+func (r Rule) key() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return r.Annotation
+}
+
+// matches reports whether obj carries the label or annotation that protects
+// it from deletion.
+func (r Rule) matches(obj *unstructured.Unstructured) bool {
+	if r.Label != "" {
+		v, ok := obj.GetLabels()[r.Label]
+		return ok && v == r.Value
+	}
+	v, ok := obj.GetAnnotations()[r.Annotation]
+	return ok && v == r.Value
+}
+
+// validator is an admission.MetaValidator that denies deletion of any object
+// matching its Rule, and allows everything else.
+type validator struct {
+	rule Rule
+}
+
+var _ admission.MetaValidator = &validator{}
+
+// DeepCopyObject returns an empty unstructured.Unstructured, since this
+// validator supports arbitrary GVRs rather than a single known type.
+func (v *validator) DeepCopyObject() runtime.Object {
+	return &unstructured.Unstructured{}
+}
+
+// ValidateCreate allows all creates; this webhook only protects against deletion.
+func (v *validator) ValidateCreate(runtime.Object, admission.AdmissionRequest) error {
+	return nil
+}
+
+// ValidateUpdate allows all updates; this webhook only protects against deletion.
+func (v *validator) ValidateUpdate(runtime.Object, runtime.Object, admission.AdmissionRequest) error {
+	return nil
+}
+
+// ValidateDelete denies the deletion if obj carries the label or annotation
+// named by v.rule.
+func (v *validator) ValidateDelete(obj runtime.Object, _ admission.AdmissionRequest) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	if !v.rule.matches(u) {
+		return nil
+	}
+	return fmt.Errorf("%s %q is protected from deletion by %s=%s", u.GetKind(), u.GetName(), v.rule.key(), v.rule.Value)
+}
+
+// NewWebhook returns an *admission.Webhook that denies deletion of any object
+// matching rule. Mount it on a webhook server with, e.g.:
+//
+//	mgr.GetWebhookServer().Register("/validate-deletion-protection",
+//		deletionprotection.NewWebhook(deletionprotection.Rule{
+//			Label: "example.com/deletion-protection",
+//			Value: "Always",
+//		}))
+//
+// NewWebhook panics if rule sets neither Label nor Annotation, or sets Value
+// to "": such a Rule matches nothing, and the resulting webhook would
+// silently allow every deletion instead of protecting anything.
+func NewWebhook(rule Rule) *admission.Webhook {
+	if rule.key() == "" {
+		panic("deletionprotection: Rule must set Label or Annotation")
+	}
+	if rule.Value == "" {
+		panic("deletionprotection: Rule.Value must not be empty")
+	}
+	return admission.ValidatingWebhookFor(&validator{rule: rule})
+}
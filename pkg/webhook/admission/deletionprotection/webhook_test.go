@@ -0,0 +1,127 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func unstructuredWith(labels, annotations map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetKind("ConfigMap")
+	u.SetName("important")
+	if labels != nil {
+		u.SetLabels(labels)
+	}
+	if annotations != nil {
+		u.SetAnnotations(annotations)
+	}
+	return u
+}
+
+func TestValidatorValidateDeleteDeniesMatchingLabel(t *testing.T) {
+	v := &validator{rule: Rule{Label: "example.com/deletion-protection", Value: "Always"}}
+	obj := unstructuredWith(map[string]string{"example.com/deletion-protection": "Always"}, nil)
+
+	if err := v.ValidateDelete(obj, admission.AdmissionRequest{}); err == nil {
+		t.Fatalf("expected deletion to be denied")
+	}
+}
+
+func TestValidatorValidateDeleteDeniesMatchingAnnotation(t *testing.T) {
+	v := &validator{rule: Rule{Annotation: "example.com/deletion-protection", Value: "Always"}}
+	obj := unstructuredWith(nil, map[string]string{"example.com/deletion-protection": "Always"})
+
+	if err := v.ValidateDelete(obj, admission.AdmissionRequest{}); err == nil {
+		t.Fatalf("expected deletion to be denied")
+	}
+}
+
+func TestValidatorValidateDeleteAllowsNonMatching(t *testing.T) {
+	v := &validator{rule: Rule{Label: "example.com/deletion-protection", Value: "Always"}}
+
+	cases := []*unstructured.Unstructured{
+		unstructuredWith(nil, nil),
+		unstructuredWith(map[string]string{"example.com/deletion-protection": "Never"}, nil),
+		unstructuredWith(map[string]string{"other-label": "Always"}, nil),
+	}
+	for i, obj := range cases {
+		if err := v.ValidateDelete(obj, admission.AdmissionRequest{}); err != nil {
+			t.Fatalf("case %d: expected deletion to be allowed, got %v", i, err)
+		}
+	}
+}
+
+func TestValidatorValidateCreateAndUpdateAlwaysAllow(t *testing.T) {
+	v := &validator{rule: Rule{Label: "example.com/deletion-protection", Value: "Always"}}
+	obj := unstructuredWith(map[string]string{"example.com/deletion-protection": "Always"}, nil)
+
+	if err := v.ValidateCreate(obj, admission.AdmissionRequest{}); err != nil {
+		t.Fatalf("ValidateCreate() = %v, want nil", err)
+	}
+	if err := v.ValidateUpdate(obj, obj, admission.AdmissionRequest{}); err != nil {
+		t.Fatalf("ValidateUpdate() = %v, want nil", err)
+	}
+}
+
+func TestRuleLabelTakesPrecedenceOverAnnotation(t *testing.T) {
+	r := Rule{Label: "l", Annotation: "a", Value: "v"}
+	if got := r.key(); got != "l" {
+		t.Fatalf("key() = %q, want %q", got, "l")
+	}
+}
+
+func TestValidatorValidateDeleteAllowsNonUnstructuredObjects(t *testing.T) {
+	v := &validator{rule: Rule{Label: "l", Value: "v"}}
+	// A decode failure elsewhere could in principle hand ValidateDelete a
+	// non-Unstructured object; it must not panic.
+	if err := v.ValidateDelete(nil, admission.AdmissionRequest{}); err != nil {
+		t.Fatalf("ValidateDelete(nil) = %v, want nil (no match, not a panic)", err)
+	}
+}
+
+func TestNewWebhookPanicsOnEmptyRule(t *testing.T) {
+	cases := []Rule{
+		{},
+		{Value: "Always"},
+		{Label: "example.com/deletion-protection"},
+		{Annotation: "example.com/deletion-protection"},
+	}
+	for i, rule := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("case %d: expected NewWebhook(%+v) to panic", i, rule)
+				}
+			}()
+			NewWebhook(rule)
+		}()
+	}
+}
+
+func TestNewWebhookAllowsValidRule(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("NewWebhook() with a valid Rule panicked: %v", r)
+		}
+	}()
+	NewWebhook(Rule{Label: "example.com/deletion-protection", Value: "Always"})
+}
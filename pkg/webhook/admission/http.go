@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	admissionReviewV1      = "admission.k8s.io/v1"
+	admissionReviewV1beta1 = "admission.k8s.io/v1beta1"
+)
+
+// typeMetaReview is used to sniff apiVersion out of a raw AdmissionReview
+// body before deciding which concrete type to unmarshal it into.
+type typeMetaReview struct {
+	metav1.TypeMeta
+}
+
+var _ http.Handler = &Webhook{}
+
+// ServeHTTP implements http.Handler. It detects whether the incoming
+// AdmissionReview speaks admission.k8s.io/v1 or the legacy
+// admission.k8s.io/v1beta1, converts a v1beta1 request into the
+// admission.k8s.io/v1 Request that Handle always deals with, and converts
+// the resulting Response back into whichever apiVersion the caller used, so
+// callers on either version get a matching response.
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		wh.writeError(w, admissionReviewV1, http.StatusBadRequest, err)
+		return
+	}
+
+	apiVersion, err := reviewAPIVersion(body)
+	if err != nil {
+		wh.writeError(w, admissionReviewV1, http.StatusBadRequest, err)
+		return
+	}
+
+	switch apiVersion {
+	case admissionReviewV1beta1:
+		review := &v1beta1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil {
+			wh.writeError(w, admissionReviewV1beta1, http.StatusBadRequest, err)
+			return
+		}
+		if review.Request == nil {
+			wh.writeError(w, admissionReviewV1beta1, http.StatusBadRequest, fmt.Errorf("admission: AdmissionReview.Request is nil"))
+			return
+		}
+		resp := wh.Handle(r.Context(), Request{AdmissionRequest: v1beta1RequestToV1(*review.Request)})
+		wh.writeResponse(w, admissionReviewV1beta1, v1beta1.AdmissionReview{
+			TypeMeta: review.TypeMeta,
+			Response: v1ResponseToV1beta1(resp.AdmissionResponse),
+		})
+	default:
+		review := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil {
+			wh.writeError(w, admissionReviewV1, http.StatusBadRequest, err)
+			return
+		}
+		if review.Request == nil {
+			wh.writeError(w, admissionReviewV1, http.StatusBadRequest, fmt.Errorf("admission: AdmissionReview.Request is nil"))
+			return
+		}
+		resp := wh.Handle(r.Context(), Request{AdmissionRequest: *review.Request})
+		wh.writeResponse(w, admissionReviewV1, admissionv1.AdmissionReview{
+			TypeMeta: review.TypeMeta,
+			Response: &resp.AdmissionResponse,
+		})
+	}
+}
+
+// reviewAPIVersion sniffs the apiVersion field out of a raw AdmissionReview
+// body without fully unmarshaling it into either wire type.
+func reviewAPIVersion(body []byte) (string, error) {
+	var t typeMetaReview
+	if err := json.Unmarshal(body, &t); err != nil {
+		return "", fmt.Errorf("admission: couldn't determine AdmissionReview apiVersion: %w", err)
+	}
+	return t.APIVersion, nil
+}
+
+// writeError responds with an AdmissionReview carrying an Errored response,
+// in the given apiVersion.
+func (wh *Webhook) writeError(w http.ResponseWriter, apiVersion string, code int, err error) {
+	resp := Errored(int32(code), err)
+	switch apiVersion {
+	case admissionReviewV1beta1:
+		wh.writeResponse(w, admissionReviewV1beta1, v1beta1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: admissionReviewV1beta1, Kind: "AdmissionReview"},
+			Response: v1ResponseToV1beta1(resp.AdmissionResponse),
+		})
+	default:
+		wh.writeResponse(w, admissionReviewV1, admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: admissionReviewV1, Kind: "AdmissionReview"},
+			Response: &resp.AdmissionResponse,
+		})
+	}
+}
+
+// writeResponse JSON-encodes review as the HTTP response body.
+func (wh *Webhook) writeResponse(w http.ResponseWriter, apiVersion string, review interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Error(err, "unable to encode AdmissionReview response", "apiVersion", apiVersion)
+	}
+}
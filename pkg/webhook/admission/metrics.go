@@ -0,0 +1,68 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_webhook_admission_requests_total",
+		Help: "Total number of admission requests handled by validating webhooks, labeled by GVK, operation, validator name and allow/deny outcome.",
+	}, []string{"group", "version", "kind", "operation", "validator", "allowed"})
+
+	requestLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "controller_runtime_webhook_admission_request_duration_seconds",
+		Help:    "Latency of admission requests handled by validating webhooks, labeled by GVK, operation and validator name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"group", "version", "kind", "operation", "validator"})
+
+	deniedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_webhook_admission_denied_total",
+		Help: "Total number of admission requests denied by validating webhooks, labeled by GVK, operation and validator name.",
+	}, []string{"group", "version", "kind", "operation", "validator"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestLatency, deniedTotal)
+}
+
+// AuditEvent captures the outcome of a single admission decision made by a
+// validatingHandler, suitable for forwarding to an external audit system.
+type AuditEvent struct {
+	GVK       schema.GroupVersionKind
+	Operation string
+	Namespace string
+	Name      string
+	UserInfo  authenticationv1.UserInfo
+	Allowed   bool
+	Reason    string
+	Warnings  []string
+}
+
+// AuditSink receives an AuditEvent for every admission decision made by a
+// validating webhook configured with WithAuditSink. Implementations should
+// not block; forward the event asynchronously if the destination is slow.
+type AuditSink interface {
+	Audit(event AuditEvent)
+}
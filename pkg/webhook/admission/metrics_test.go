@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// denyingValidator is a MetaValidator that always denies with err.
+type denyingValidator struct {
+	err error
+}
+
+func (v *denyingValidator) DeepCopyObject() runtime.Object { return &decodableObject{} }
+func (v *denyingValidator) ValidateCreate(runtime.Object, AdmissionRequest) error {
+	return v.err
+}
+func (v *denyingValidator) ValidateUpdate(_, _ runtime.Object, _ AdmissionRequest) error {
+	return v.err
+}
+func (v *denyingValidator) ValidateDelete(runtime.Object, AdmissionRequest) error {
+	return v.err
+}
+
+var _ MetaValidator = &denyingValidator{}
+
+// fakeAuditSink records every AuditEvent it's given.
+type fakeAuditSink struct {
+	events []AuditEvent
+}
+
+func (s *fakeAuditSink) Audit(event AuditEvent) {
+	s.events = append(s.events, event)
+}
+
+var _ AuditSink = &fakeAuditSink{}
+
+func TestHandleDeniedRecordsMetricsAndAuditEvent(t *testing.T) {
+	wantErr := errors.New("quota exceeded")
+	sink := &fakeAuditSink{}
+	h := newValidatingHandler(&denyingValidator{err: wantErr}, WithAuditSink(sink))
+	h.decoder = NewDecoder(nil)
+
+	validatorName := "*admission.denyingValidator"
+	deniedBefore := testutil.ToFloat64(deniedTotal.WithLabelValues("apps", "v1", "Deployment", "CREATE", validatorName))
+
+	req := Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Namespace: "default",
+			Name:      "my-deploy",
+			UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+			Object:    runtime.RawExtension{Raw: []byte("{}")},
+		},
+	}
+
+	resp := h.Handle(context.Background(), req)
+	if resp.Allowed {
+		t.Fatalf("Handle() Allowed = true, want false")
+	}
+
+	deniedAfter := testutil.ToFloat64(deniedTotal.WithLabelValues("apps", "v1", "Deployment", "CREATE", validatorName))
+	if deniedAfter != deniedBefore+1 {
+		t.Fatalf("deniedTotal = %v, want %v", deniedAfter, deniedBefore+1)
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("got %d audit events, want 1", len(sink.events))
+	}
+	event := sink.events[0]
+	if event.Allowed {
+		t.Fatalf("AuditEvent.Allowed = true, want false")
+	}
+	if event.Reason != wantErr.Error() {
+		t.Fatalf("AuditEvent.Reason = %q, want %q", event.Reason, wantErr.Error())
+	}
+	if event.Namespace != "default" || event.Name != "my-deploy" || event.UserInfo.Username != "alice" {
+		t.Fatalf("AuditEvent = %+v, unexpected request fields", event)
+	}
+}
+
+func TestHandleConnectIsInstrumentedAndAllowed(t *testing.T) {
+	sink := &fakeAuditSink{}
+	h := newValidatingHandler(&denyingValidator{}, WithAuditSink(sink))
+	h.decoder = NewDecoder(nil)
+
+	req := Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Connect,
+			Kind:      metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "PodExecOptions"},
+		},
+	}
+
+	resp := h.Handle(context.Background(), req)
+	if !resp.Allowed {
+		t.Fatalf("Handle() Allowed = false, want true for CONNECT")
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("got %d audit events for CONNECT, want 1", len(sink.events))
+	}
+	if !sink.events[0].Allowed {
+		t.Fatalf("AuditEvent.Allowed = false, want true for CONNECT")
+	}
+}
+
+func TestHandleDecodeFailureIsInstrumented(t *testing.T) {
+	sink := &fakeAuditSink{}
+	h := newValidatingHandler(&denyingValidator{}, WithAuditSink(sink))
+	h.decoder = NewDecoder(nil)
+
+	req := Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			// Object.Raw is empty, so DecodeRaw fails before the validator runs.
+		},
+	}
+
+	resp := h.Handle(context.Background(), req)
+	if resp.Allowed {
+		t.Fatalf("Handle() Allowed = true, want false for a decode failure")
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("got %d audit events for a decode failure, want 1", len(sink.events))
+	}
+	if sink.events[0].Allowed {
+		t.Fatalf("AuditEvent.Allowed = true, want false for a decode failure")
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Operation is the kind of change an AdmissionRequest asks to make, mirroring
+// admission.k8s.io's Operation field without tying callers to either the v1
+// or v1beta1 wire type.
+type Operation string
+
+const (
+	// Create indicates the object is being created.
+	Create Operation = "CREATE"
+	// Update indicates the object is being updated.
+	Update Operation = "UPDATE"
+	// Delete indicates the object is being deleted.
+	Delete Operation = "DELETE"
+	// Connect indicates a connect subresource (e.g. exec, attach) request.
+	Connect Operation = "CONNECT"
+)
+
+// AdmissionRequest is a version-agnostic view of an incoming admission
+// request: the fields MetaValidator implementations need, independent of
+// whether the request arrived as an admission.k8s.io/v1 or the removed (in
+// Kubernetes 1.22+) admission.k8s.io/v1beta1 AdmissionReview. validatingHandler
+// converts the wire request into an AdmissionRequest once, before dispatching
+// to the configured validator.
+//
+// It carries every field of the wire AdmissionRequest except Object and
+// OldObject: those come decoded into validator method parameters instead, so
+// duplicating the raw bytes here would serve no purpose.
+type AdmissionRequest struct {
+	UID                types.UID
+	Kind               metav1.GroupVersionKind
+	Resource           metav1.GroupVersionResource
+	SubResource        string
+	RequestKind        *metav1.GroupVersionKind
+	RequestResource    *metav1.GroupVersionResource
+	RequestSubResource string
+	Namespace          string
+	Name               string
+	Operation          Operation
+	UserInfo           authenticationv1.UserInfo
+	DryRun             *bool
+	Options            runtime.RawExtension
+}
+
+// admissionRequestFromV1beta1 converts an admission.k8s.io/v1beta1
+// AdmissionRequest into its version-agnostic equivalent.
+func admissionRequestFromV1beta1(r v1beta1.AdmissionRequest) AdmissionRequest {
+	return AdmissionRequest{
+		UID:                r.UID,
+		Kind:               r.Kind,
+		Resource:           r.Resource,
+		SubResource:        r.SubResource,
+		RequestKind:        r.RequestKind,
+		RequestResource:    r.RequestResource,
+		RequestSubResource: r.RequestSubResource,
+		Namespace:          r.Namespace,
+		Name:               r.Name,
+		Operation:          Operation(r.Operation),
+		UserInfo:           r.UserInfo,
+		DryRun:             r.DryRun,
+		Options:            r.Options,
+	}
+}
+
+// admissionRequestFromV1 converts an admission.k8s.io/v1 AdmissionRequest
+// into its version-agnostic equivalent.
+func admissionRequestFromV1(r admissionv1.AdmissionRequest) AdmissionRequest {
+	return AdmissionRequest{
+		UID:                r.UID,
+		Kind:               r.Kind,
+		Resource:           r.Resource,
+		SubResource:        r.SubResource,
+		RequestKind:        r.RequestKind,
+		RequestResource:    r.RequestResource,
+		RequestSubResource: r.RequestSubResource,
+		Namespace:          r.Namespace,
+		Name:               r.Name,
+		Operation:          Operation(r.Operation),
+		UserInfo:           r.UserInfo,
+		DryRun:             r.DryRun,
+		Options:            r.Options,
+	}
+}
+
+// toV1beta1 converts back to the fields of a v1beta1.AdmissionRequest that
+// AdmissionRequest carries, for adapting legacy v1beta1-based validators via
+// MetaValidatorV1beta1Wrapper. Object and OldObject are left zero: legacy
+// validators receive those separately via the decoded object, not through
+// the request.
+func (r AdmissionRequest) toV1beta1() v1beta1.AdmissionRequest {
+	return v1beta1.AdmissionRequest{
+		UID:                r.UID,
+		Kind:               r.Kind,
+		Resource:           r.Resource,
+		SubResource:        r.SubResource,
+		RequestKind:        r.RequestKind,
+		RequestResource:    r.RequestResource,
+		RequestSubResource: r.RequestSubResource,
+		Namespace:          r.Namespace,
+		Name:               r.Name,
+		Operation:          v1beta1.Operation(r.Operation),
+		UserInfo:           r.UserInfo,
+		DryRun:             r.DryRun,
+		Options:            r.Options,
+	}
+}
+
+// v1beta1RequestToV1 converts the wire admission.k8s.io/v1beta1
+// AdmissionRequest ServeHTTP decoded into the admission.k8s.io/v1 shape that
+// Request always carries internally, field for field; the two wire types
+// have always been identical in shape, only differing in package.
+func v1beta1RequestToV1(r v1beta1.AdmissionRequest) admissionv1.AdmissionRequest {
+	return admissionv1.AdmissionRequest{
+		UID:                r.UID,
+		Kind:               r.Kind,
+		Resource:           r.Resource,
+		SubResource:        r.SubResource,
+		RequestKind:        r.RequestKind,
+		RequestResource:    r.RequestResource,
+		RequestSubResource: r.RequestSubResource,
+		Name:               r.Name,
+		Namespace:          r.Namespace,
+		Operation:          admissionv1.Operation(r.Operation),
+		UserInfo:           r.UserInfo,
+		Object:             r.Object,
+		OldObject:          r.OldObject,
+		DryRun:             r.DryRun,
+		Options:            r.Options,
+	}
+}
+
+// v1ResponseToV1beta1 converts an admission.k8s.io/v1 AdmissionResponse back
+// into the v1beta1 shape, so ServeHTTP can answer a v1beta1 caller with a
+// v1beta1 AdmissionReview. Warnings requires k8s.io/api >= v0.19.0 on both
+// the v1beta1 and v1 AdmissionResponse types; see allowedWithWarnings.
+func v1ResponseToV1beta1(r admissionv1.AdmissionResponse) *v1beta1.AdmissionResponse {
+	return &v1beta1.AdmissionResponse{
+		UID:              r.UID,
+		Allowed:          r.Allowed,
+		Result:           r.Result,
+		Patch:            r.Patch,
+		PatchType:        (*v1beta1.PatchType)(r.PatchType),
+		AuditAnnotations: r.AuditAnnotations,
+		Warnings:         r.Warnings,
+	}
+}
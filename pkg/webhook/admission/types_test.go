@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestAdmissionRequestFromV1beta1(t *testing.T) {
+	r := v1beta1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace: "default",
+		Name:      "my-deploy",
+		Operation: v1beta1.Update,
+		UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+		DryRun:    boolPtr(true),
+	}
+
+	got := admissionRequestFromV1beta1(r)
+	if got.Kind != r.Kind || got.Namespace != r.Namespace || got.Name != r.Name ||
+		got.Operation != Update || got.UserInfo.Username != r.UserInfo.Username || *got.DryRun != *r.DryRun {
+		t.Fatalf("admissionRequestFromV1beta1() = %+v, want fields to match %+v", got, r)
+	}
+}
+
+func TestAdmissionRequestFromV1(t *testing.T) {
+	r := admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace: "default",
+		Name:      "my-deploy",
+		Operation: admissionv1.Delete,
+		UserInfo:  authenticationv1.UserInfo{Username: "bob"},
+		DryRun:    boolPtr(false),
+	}
+
+	got := admissionRequestFromV1(r)
+	if got.Kind != r.Kind || got.Namespace != r.Namespace || got.Name != r.Name ||
+		got.Operation != Delete || got.UserInfo.Username != r.UserInfo.Username || *got.DryRun != *r.DryRun {
+		t.Fatalf("admissionRequestFromV1() = %+v, want fields to match %+v", got, r)
+	}
+}
+
+func TestAdmissionRequestToV1beta1RoundTrips(t *testing.T) {
+	ar := AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace: "default",
+		Name:      "my-deploy",
+		Operation: Create,
+		UserInfo:  authenticationv1.UserInfo{Username: "carol"},
+		DryRun:    boolPtr(true),
+	}
+
+	got := ar.toV1beta1()
+	if got.Operation != v1beta1.Create || got.Namespace != ar.Namespace || got.Name != ar.Name {
+		t.Fatalf("toV1beta1() = %+v, want fields to match %+v", got, ar)
+	}
+	back := admissionRequestFromV1beta1(got)
+	if back.Operation != ar.Operation || back.Namespace != ar.Namespace || back.Name != ar.Name ||
+		back.UserInfo.Username != ar.UserInfo.Username {
+		t.Fatalf("round-trip through toV1beta1()/admissionRequestFromV1beta1() = %+v, want %+v", back, ar)
+	}
+}
@@ -18,12 +18,26 @@ package admission
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/api/admission/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var log = logf.Log.WithName("admission").WithName("validator")
+
 // Validator defines functions for validating an operation
 type Validator interface {
 	runtime.Object
@@ -32,9 +46,39 @@ type Validator interface {
 	ValidateDelete() error
 }
 
+// ValidatorWithWarnings defines functions for validating an operation,
+// additionally returning warnings that should be surfaced to the requesting
+// user even when the operation is allowed (e.g. use of a deprecated field).
+type ValidatorWithWarnings interface {
+	runtime.Object
+	ValidateCreate() (warnings []string, err error)
+	ValidateUpdate(old runtime.Object) (warnings []string, err error)
+	ValidateDelete() (warnings []string, err error)
+}
+
 // MetaValidator defines functions for validating an operation that also
-// receive the request, giving access to e.g. the user info
+// receive the request, giving access to e.g. the user info. Unlike Validator,
+// it is not tied to the admission.k8s.io/v1beta1 wire format: req is the
+// version-agnostic AdmissionRequest.
 type MetaValidator interface {
+	// DeepCopyObject returns an empty object of the correct type
+	DeepCopyObject() runtime.Object
+	// ValidateCreate validates that the passed object can be created,
+	// and allows for the request to be examined
+	ValidateCreate(runtime.Object, AdmissionRequest) error
+	// ValidateUpdate validates that the object can be updated from `old` to `obj`,
+	// and allows for the request to be examined
+	ValidateUpdate(obj runtime.Object, old runtime.Object, req AdmissionRequest) error
+	// ValidateDelete validates that the passed object can be deleted,
+	// and allows for the request to be examined
+	ValidateDelete(runtime.Object, AdmissionRequest) error
+}
+
+// MetaValidatorV1beta1 is the legacy, admission.k8s.io/v1beta1-specific form
+// of MetaValidator. v1beta1 was removed in Kubernetes 1.22+; new validators
+// should implement MetaValidator directly. Existing implementations can keep
+// working unchanged by wrapping themselves with NewMetaValidatorV1beta1Wrapper.
+type MetaValidatorV1beta1 interface {
 	// DeepCopyObject returns an empty object of the correct type
 	DeepCopyObject() runtime.Object
 	// ValidateCreate validates that the passed object can be created,
@@ -43,11 +87,70 @@ type MetaValidator interface {
 	// ValidateUpdate validates that the object can be updated from `old` to `obj`,
 	// and allows for the request to be examined
 	ValidateUpdate(obj runtime.Object, old runtime.Object, req v1beta1.AdmissionRequest) error
-	// ValidateCreate validates that the passed object can be deleted,
+	// ValidateDelete validates that the passed object can be deleted,
 	// and allows for the request to be examined
 	ValidateDelete(runtime.Object, v1beta1.AdmissionRequest) error
 }
 
+// MetaValidatorV1beta1Wrapper adapts a MetaValidatorV1beta1 into a
+// MetaValidator, converting the version-agnostic AdmissionRequest back into
+// a v1beta1.AdmissionRequest before delegating.
+type MetaValidatorV1beta1Wrapper struct {
+	Validator MetaValidatorV1beta1
+}
+
+// NewMetaValidatorV1beta1Wrapper creates a MetaValidator out of a
+// MetaValidatorV1beta1 using a MetaValidatorV1beta1Wrapper.
+func NewMetaValidatorV1beta1Wrapper(validator MetaValidatorV1beta1) MetaValidator {
+	return &MetaValidatorV1beta1Wrapper{Validator: validator}
+}
+
+// DeepCopyObject delegates to the wrapped MetaValidatorV1beta1.
+func (w *MetaValidatorV1beta1Wrapper) DeepCopyObject() runtime.Object {
+	return w.Validator.DeepCopyObject()
+}
+
+// ValidateCreate delegates to the wrapped MetaValidatorV1beta1.
+func (w *MetaValidatorV1beta1Wrapper) ValidateCreate(obj runtime.Object, req AdmissionRequest) error {
+	return w.Validator.ValidateCreate(obj, req.toV1beta1())
+}
+
+// ValidateUpdate delegates to the wrapped MetaValidatorV1beta1.
+func (w *MetaValidatorV1beta1Wrapper) ValidateUpdate(obj, old runtime.Object, req AdmissionRequest) error {
+	return w.Validator.ValidateUpdate(obj, old, req.toV1beta1())
+}
+
+// ValidateDelete delegates to the wrapped MetaValidatorV1beta1.
+func (w *MetaValidatorV1beta1Wrapper) ValidateDelete(obj runtime.Object, req AdmissionRequest) error {
+	return w.Validator.ValidateDelete(obj, req.toV1beta1())
+}
+
+var _ MetaValidator = &MetaValidatorV1beta1Wrapper{}
+
+// MetaValidatorWithWarnings is the request-aware counterpart of
+// ValidatorWithWarnings: each method may additionally return non-fatal
+// warnings which are surfaced to the requesting user regardless of the
+// allow/deny decision.
+//
+// The returned error may be an *apierrors.StatusError, or the result of
+// calling ToAggregate on a field.ErrorList, in which case the denial
+// response will carry the corresponding structured `status.details.causes`
+// instead of a flat message. A field.ErrorList can't be returned directly:
+// it has no Error() string method, so it doesn't implement error.
+type MetaValidatorWithWarnings interface {
+	// DeepCopyObject returns an empty object of the correct type
+	DeepCopyObject() runtime.Object
+	// ValidateCreate validates that the passed object can be created,
+	// and allows for the request to be examined
+	ValidateCreate(runtime.Object, AdmissionRequest) (warnings []string, err error)
+	// ValidateUpdate validates that the object can be updated from `old` to `obj`,
+	// and allows for the request to be examined
+	ValidateUpdate(obj runtime.Object, old runtime.Object, req AdmissionRequest) (warnings []string, err error)
+	// ValidateDelete validates that the passed object can be deleted,
+	// and allows for the request to be examined
+	ValidateDelete(runtime.Object, AdmissionRequest) (warnings []string, err error)
+}
+
 // ValidatorWrapper wraps a Validator in a MetaValidator for compatibility
 type ValidatorWrapper struct {
 	Validator Validator
@@ -66,34 +169,338 @@ func (v *ValidatorWrapper) DeepCopyObject() runtime.Object {
 
 // ValidateCreate checks that `obj` can be created. It delegates to calling `ValidateCreate`
 // on the object, and assumes that it implements Validator
-func (v *ValidatorWrapper) ValidateCreate(obj runtime.Object, _ v1beta1.AdmissionRequest) error {
+func (v *ValidatorWrapper) ValidateCreate(obj runtime.Object, _ AdmissionRequest) error {
 	return obj.(Validator).ValidateCreate()
 }
 
 // ValidateDelete checks that `obj` can be deleted. It delegates to calling `ValidateDelete`
 // on the object, and assumes that it implements Validator
-func (v *ValidatorWrapper) ValidateDelete(obj runtime.Object, _ v1beta1.AdmissionRequest) error {
+func (v *ValidatorWrapper) ValidateDelete(obj runtime.Object, _ AdmissionRequest) error {
 	return obj.(Validator).ValidateDelete()
 }
 
 // ValidateUpdate checks that `obj` can be updated. It delegates to calling `ValidateUpdate`
 // on the object, and assumes that it implements Validator
-func (v *ValidatorWrapper) ValidateUpdate(obj, old runtime.Object, _ v1beta1.AdmissionRequest) error {
+func (v *ValidatorWrapper) ValidateUpdate(obj, old runtime.Object, _ AdmissionRequest) error {
 	return obj.(Validator).ValidateUpdate(old)
 }
 
 var _ MetaValidator = &ValidatorWrapper{}
 
+// ValidatorWrapperWithWarnings wraps a ValidatorWithWarnings in a
+// MetaValidatorWithWarnings for compatibility. It also accepts a plain
+// Validator, in which case it always reports no warnings.
+type ValidatorWrapperWithWarnings struct {
+	Validator runtime.Object
+}
+
+// NewValidatorWrapperWithWarnings creates a MetaValidatorWithWarnings out of a
+// ValidatorWithWarnings or a Validator using a ValidatorWrapperWithWarnings.
+// Validators implementing ValidatorWithWarnings have their warnings
+// propagated; plain Validators always return no warnings.
+func NewValidatorWrapperWithWarnings(validator runtime.Object) MetaValidatorWithWarnings {
+	return &ValidatorWrapperWithWarnings{Validator: validator}
+}
+
+// DeepCopyObject creates an empty object of the correct type. It delegates to
+// DeepCopyObject of the underlying Validator
+func (v *ValidatorWrapperWithWarnings) DeepCopyObject() runtime.Object {
+	return v.Validator.DeepCopyObject()
+}
+
+// ValidateCreate checks that `obj` can be created. It delegates to calling `ValidateCreate`
+// on the object, and assumes that it implements Validator or ValidatorWithWarnings
+func (v *ValidatorWrapperWithWarnings) ValidateCreate(obj runtime.Object, _ AdmissionRequest) ([]string, error) {
+	if validator, ok := obj.(ValidatorWithWarnings); ok {
+		return validator.ValidateCreate()
+	}
+	return nil, obj.(Validator).ValidateCreate()
+}
+
+// ValidateDelete checks that `obj` can be deleted. It delegates to calling `ValidateDelete`
+// on the object, and assumes that it implements Validator or ValidatorWithWarnings
+func (v *ValidatorWrapperWithWarnings) ValidateDelete(obj runtime.Object, _ AdmissionRequest) ([]string, error) {
+	if validator, ok := obj.(ValidatorWithWarnings); ok {
+		return validator.ValidateDelete()
+	}
+	return nil, obj.(Validator).ValidateDelete()
+}
+
+// ValidateUpdate checks that `obj` can be updated. It delegates to calling `ValidateUpdate`
+// on the object, and assumes that it implements Validator or ValidatorWithWarnings
+func (v *ValidatorWrapperWithWarnings) ValidateUpdate(obj, old runtime.Object, _ AdmissionRequest) ([]string, error) {
+	if validator, ok := obj.(ValidatorWithWarnings); ok {
+		return validator.ValidateUpdate(old)
+	}
+	return nil, obj.(Validator).ValidateUpdate(old)
+}
+
+var _ MetaValidatorWithWarnings = &ValidatorWrapperWithWarnings{}
+
+// DryRunAwareValidator is implemented by validators that want to short-circuit
+// expensive, side-effecting checks (e.g. a lookup confirming a referenced
+// Secret exists) when handling a dry-run request. When a MetaValidator also
+// implements DryRunAwareValidator, validatingHandler dispatches dry-run
+// requests (req.DryRun != nil && *req.DryRun) to these methods instead of the
+// regular Validate* methods.
+//
+// Implementations MUST NOT mutate cluster state from these methods: webhooks
+// that only implement DryRunAwareValidator can safely declare
+// sideEffects=NoneOnDryRun.
+type DryRunAwareValidator interface {
+	// ValidateCreateDryRun validates that the passed object can be created,
+	// without performing any side effects.
+	ValidateCreateDryRun(obj runtime.Object, req AdmissionRequest) error
+	// ValidateUpdateDryRun validates that the object can be updated from
+	// `old` to `obj`, without performing any side effects.
+	ValidateUpdateDryRun(obj runtime.Object, old runtime.Object, req AdmissionRequest) error
+	// ValidateDeleteDryRun validates that the passed object can be deleted,
+	// without performing any side effects.
+	ValidateDeleteDryRun(obj runtime.Object, req AdmissionRequest) error
+}
+
+// ClientInjector is implemented by validators that need a client.Client to
+// look up cluster state (e.g. confirming a referenced object exists) while
+// validating. It mirrors DecoderInjector: the webhook server injects the
+// manager's client into any validator that implements this interface.
+type ClientInjector interface {
+	// InjectClient injects the client.
+	InjectClient(c client.Client) error
+}
+
+// objectValidator is the common subset of MetaValidator and
+// MetaValidatorWithWarnings. A concrete type can only ever implement one of
+// the two (their Validate* methods share names but differ in return
+// signature), so validatingHandler stores its validator as an objectValidator
+// and recovers the richer interface with a type assertion in Handle.
+type objectValidator interface {
+	// DeepCopyObject returns an empty object of the correct type
+	DeepCopyObject() runtime.Object
+}
+
+// WebhookOption configures a validating Webhook built by ValidatingWebhookFor,
+// ValidatingWebhookForWithWarnings or ValidatingWebhookForAll.
+type WebhookOption func(*validatingHandler)
+
+// WithAuditSink configures the webhook to forward every admission decision to sink.
+func WithAuditSink(sink AuditSink) WebhookOption {
+	return func(h *validatingHandler) {
+		h.auditSink = sink
+	}
+}
+
+// WithClient injects c into the webhook's validator, the same way the
+// manager's client would be injected at runtime if the validator implements
+// ClientInjector. It's a convenience for constructing a fully wired Webhook
+// outside of a Manager, e.g. in tests.
+func WithClient(c client.Client) WebhookOption {
+	return func(h *validatingHandler) {
+		if err := h.InjectClient(c); err != nil {
+			panic(fmt.Sprintf("admission: WithClient: %v", err))
+		}
+	}
+}
+
 // ValidatingWebhookFor creates a new Webhook for validating the provided type.
-func ValidatingWebhookFor(validator MetaValidator) *Webhook {
+func ValidatingWebhookFor(validator MetaValidator, opts ...WebhookOption) *Webhook {
+	return &Webhook{
+		Handler: newValidatingHandler(validator, opts...),
+	}
+}
+
+// ValidatingWebhookForWithWarnings creates a new Webhook for validating the
+// provided type using a validator that can also return non-fatal warnings.
+func ValidatingWebhookForWithWarnings(validator MetaValidatorWithWarnings, opts ...WebhookOption) *Webhook {
 	return &Webhook{
-		Handler: &validatingHandler{validator: validator},
+		Handler: newValidatingHandler(validator, opts...),
+	}
+}
+
+// newValidatingHandler builds a validatingHandler for validator, applying opts.
+func newValidatingHandler(validator objectValidator, opts ...WebhookOption) *validatingHandler {
+	h := &validatingHandler{validator: validator}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
+}
+
+// ChainMode controls how a ChainValidator combines the results of its
+// component validators.
+type ChainMode int
+
+const (
+	// FailFast stops at the first validator that denies the request and
+	// returns its reason as-is. This matches the behaviour of a single
+	// MetaValidator.
+	FailFast ChainMode = iota
+	// Aggregate runs every validator regardless of earlier denials, and
+	// concatenates all denial reasons into a single response.
+	Aggregate
+)
+
+// ChainableValidator is the subset of MetaValidator and
+// MetaValidatorWithWarnings that ChainValidator requires of each validator it
+// composes at compile time: only DeepCopyObject. ChainValidator recovers the
+// richer interface with a type assertion at dispatch time; an element that
+// implements neither MetaValidator nor MetaValidatorWithWarnings type-checks
+// fine here but is rejected with an error (not a panic) the first time it's
+// dispatched to.
+type ChainableValidator = objectValidator
+
+// ChainValidator composes several validators into a single
+// MetaValidatorWithWarnings, letting operators layer concerns (schema
+// checks, policy checks, deletion protection, quota checks, ...) behind one
+// webhook configuration instead of stacking several. All chained validators
+// are expected to operate on the same object type.
+//
+// Each element of Validators may implement either MetaValidator or
+// MetaValidatorWithWarnings; warnings from elements that implement the
+// latter are always propagated, and combined across elements the same way
+// Mode combines denial reasons.
+type ChainValidator struct {
+	Validators []ChainableValidator
+	Mode       ChainMode
+}
+
+var _ MetaValidatorWithWarnings = &ChainValidator{}
+var _ DecoderInjector = &ChainValidator{}
+var _ ClientInjector = &ChainValidator{}
+
+// ValidatingWebhookForAll creates a new Webhook that runs each of validators
+// in turn and combines their results according to mode. Use
+// ValidatingWebhookFor(&ChainValidator{...}, opts...) directly if you also
+// need a WebhookOption such as WithAuditSink on the combined webhook.
+func ValidatingWebhookForAll(mode ChainMode, validators ...ChainableValidator) *Webhook {
+	if len(validators) == 0 {
+		panic("admission: ValidatingWebhookForAll requires at least one validator")
+	}
+	return ValidatingWebhookForWithWarnings(&ChainValidator{Validators: validators, Mode: mode})
+}
+
+// DeepCopyObject returns an empty object of the correct type. It delegates to
+// the first chained validator, since all of them are expected to validate the
+// same type.
+func (c *ChainValidator) DeepCopyObject() runtime.Object {
+	if len(c.Validators) == 0 {
+		panic("admission: ChainValidator has no validators")
+	}
+	return c.Validators[0].DeepCopyObject()
+}
+
+// ValidateCreate runs ValidateCreate on each chained validator in turn.
+func (c *ChainValidator) ValidateCreate(obj runtime.Object, req AdmissionRequest) ([]string, error) {
+	return c.run(func(v ChainableValidator) ([]string, error) {
+		return validateCreate(v, obj, req)
+	})
+}
+
+// ValidateUpdate runs ValidateUpdate on each chained validator in turn.
+func (c *ChainValidator) ValidateUpdate(obj, old runtime.Object, req AdmissionRequest) ([]string, error) {
+	return c.run(func(v ChainableValidator) ([]string, error) {
+		return validateUpdate(v, obj, old, req)
+	})
+}
+
+// ValidateDelete runs ValidateDelete on each chained validator in turn.
+func (c *ChainValidator) ValidateDelete(obj runtime.Object, req AdmissionRequest) ([]string, error) {
+	return c.run(func(v ChainableValidator) ([]string, error) {
+		return validateDelete(v, obj, req)
+	})
+}
+
+// validateCreate dispatches ValidateCreate to v, preferring
+// MetaValidatorWithWarnings when v implements it so its warnings are
+// surfaced; plain MetaValidators report no warnings. v implementing neither
+// interface is a misconfiguration, reported as a denial rather than a panic
+// so a single bad chain element fails closed instead of crashing the request.
+func validateCreate(v ChainableValidator, obj runtime.Object, req AdmissionRequest) ([]string, error) {
+	if wv, ok := v.(MetaValidatorWithWarnings); ok {
+		return wv.ValidateCreate(obj, req)
+	}
+	if mv, ok := v.(MetaValidator); ok {
+		return nil, mv.ValidateCreate(obj, req)
+	}
+	return nil, fmt.Errorf("admission: chained validator %T implements neither MetaValidator nor MetaValidatorWithWarnings", v)
+}
+
+// validateUpdate dispatches ValidateUpdate to v; see validateCreate.
+func validateUpdate(v ChainableValidator, obj, old runtime.Object, req AdmissionRequest) ([]string, error) {
+	if wv, ok := v.(MetaValidatorWithWarnings); ok {
+		return wv.ValidateUpdate(obj, old, req)
+	}
+	if mv, ok := v.(MetaValidator); ok {
+		return nil, mv.ValidateUpdate(obj, old, req)
+	}
+	return nil, fmt.Errorf("admission: chained validator %T implements neither MetaValidator nor MetaValidatorWithWarnings", v)
+}
+
+// validateDelete dispatches ValidateDelete to v; see validateCreate.
+func validateDelete(v ChainableValidator, obj runtime.Object, req AdmissionRequest) ([]string, error) {
+	if wv, ok := v.(MetaValidatorWithWarnings); ok {
+		return wv.ValidateDelete(obj, req)
+	}
+	if mv, ok := v.(MetaValidator); ok {
+		return nil, mv.ValidateDelete(obj, req)
+	}
+	return nil, fmt.Errorf("admission: chained validator %T implements neither MetaValidator nor MetaValidatorWithWarnings", v)
+}
+
+// run calls call for each chained validator, combining denial reasons and
+// warnings according to c.Mode. In FailFast mode, the warnings collected so
+// far are still returned alongside the first denial. In Aggregate mode every
+// validator runs regardless of earlier denials, and all denial reasons and
+// all warnings are concatenated into a single response.
+func (c *ChainValidator) run(call func(ChainableValidator) ([]string, error)) ([]string, error) {
+	var allWarnings []string
+	var reasons []string
+	for _, v := range c.Validators {
+		warnings, err := call(v)
+		allWarnings = append(allWarnings, warnings...)
+		if err != nil {
+			if c.Mode == FailFast {
+				return allWarnings, err
+			}
+			reasons = append(reasons, err.Error())
+		}
+	}
+	if len(reasons) > 0 {
+		return allWarnings, errors.New(strings.Join(reasons, "; "))
+	}
+	return allWarnings, nil
+}
+
+// InjectDecoder fans the decoder out to every chained validator that
+// implements DecoderInjector.
+func (c *ChainValidator) InjectDecoder(d *Decoder) error {
+	for _, v := range c.Validators {
+		if injector, ok := v.(DecoderInjector); ok {
+			if err := injector.InjectDecoder(d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// InjectClient fans the client out to every chained validator that
+// implements ClientInjector.
+func (c *ChainValidator) InjectClient(cl client.Client) error {
+	for _, v := range c.Validators {
+		if injector, ok := v.(ClientInjector); ok {
+			if err := injector.InjectClient(cl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 type validatingHandler struct {
-	validator MetaValidator
+	validator objectValidator
 	decoder   *Decoder
+	client    client.Client
+	auditSink AuditSink
 }
 
 var _ DecoderInjector = &validatingHandler{}
@@ -104,57 +511,260 @@ func (h *validatingHandler) InjectDecoder(d *Decoder) error {
 	return nil
 }
 
+var _ ClientInjector = &validatingHandler{}
+
+// InjectClient injects the client into a validatingHandler, forwarding it on
+// to the underlying validator if it implements ClientInjector.
+func (h *validatingHandler) InjectClient(c client.Client) error {
+	h.client = c
+	if injector, ok := h.validator.(ClientInjector); ok {
+		return injector.InjectClient(c)
+	}
+	return nil
+}
+
 // Handle handles admission requests.
 func (h *validatingHandler) Handle(ctx context.Context, req Request) Response {
 	if h.validator == nil {
 		panic("validator should never be nil")
 	}
 
+	withWarnings, hasWarnings := h.validator.(MetaValidatorWithWarnings)
+	plain, hasPlain := h.validator.(MetaValidator)
+	dryRunValidator, withDryRun := h.validator.(DryRunAwareValidator)
+
+	// ar is the version-agnostic view of req.AdmissionRequest that gets
+	// passed to the validator, so validator implementations don't need to
+	// hard-code either the admission.k8s.io/v1 or the (removed in Kubernetes
+	// 1.22+) admission.k8s.io/v1beta1 wire type. ServeHTTP has already
+	// negotiated the incoming AdmissionReview's apiVersion and converted the
+	// request to admission.k8s.io/v1 by the time it reaches Handle, and will
+	// convert the Response back to whichever apiVersion the caller used.
+	ar := admissionRequestFromV1(req.AdmissionRequest)
+	dryRun := ar.DryRun != nil && *ar.DryRun
+	start := time.Now()
+
 	// Get the object in the request
 	obj := h.validator.DeepCopyObject()
-	if req.Operation == v1beta1.Create {
+	if req.Operation == admissionv1.Create {
 		err := h.decoder.Decode(req, obj)
 		if err != nil {
-			return Errored(http.StatusBadRequest, err)
+			return h.errored(ar, start, http.StatusBadRequest, err)
 		}
 
-		err = h.validator.ValidateCreate(obj, req.AdmissionRequest)
-		if err != nil {
-			return Denied(err.Error())
+		var warnings []string
+		switch {
+		case dryRun && withDryRun:
+			err = dryRunValidator.ValidateCreateDryRun(obj, ar)
+		case hasWarnings:
+			warnings, err = withWarnings.ValidateCreate(obj, ar)
+		case hasPlain:
+			err = plain.ValidateCreate(obj, ar)
 		}
+		return h.finish(ar, start, warnings, err)
 	}
 
-	if req.Operation == v1beta1.Update {
+	if req.Operation == admissionv1.Update {
 		oldObj := obj.DeepCopyObject()
 
 		err := h.decoder.DecodeRaw(req.Object, obj)
 		if err != nil {
-			return Errored(http.StatusBadRequest, err)
+			return h.errored(ar, start, http.StatusBadRequest, err)
 		}
 		err = h.decoder.DecodeRaw(req.OldObject, oldObj)
 		if err != nil {
-			return Errored(http.StatusBadRequest, err)
+			return h.errored(ar, start, http.StatusBadRequest, err)
 		}
 
-		err = h.validator.ValidateUpdate(obj, oldObj, req.AdmissionRequest)
-		if err != nil {
-			return Denied(err.Error())
+		var warnings []string
+		switch {
+		case dryRun && withDryRun:
+			err = dryRunValidator.ValidateUpdateDryRun(obj, oldObj, ar)
+		case hasWarnings:
+			warnings, err = withWarnings.ValidateUpdate(obj, oldObj, ar)
+		case hasPlain:
+			err = plain.ValidateUpdate(obj, oldObj, ar)
 		}
+		return h.finish(ar, start, warnings, err)
 	}
 
-	if req.Operation == v1beta1.Delete {
+	if req.Operation == admissionv1.Delete {
 		// In reference to PR: https://github.com/kubernetes/kubernetes/pull/76346
 		// OldObject contains the object being deleted
 		err := h.decoder.DecodeRaw(req.OldObject, obj)
 		if err != nil {
-			return Errored(http.StatusBadRequest, err)
+			return h.errored(ar, start, http.StatusBadRequest, err)
 		}
 
-		err = h.validator.ValidateDelete(obj, req.AdmissionRequest)
-		if err != nil {
+		var warnings []string
+		switch {
+		case dryRun && withDryRun:
+			err = dryRunValidator.ValidateDeleteDryRun(obj, ar)
+		case hasWarnings:
+			warnings, err = withWarnings.ValidateDelete(obj, ar)
+		case hasPlain:
+			err = plain.ValidateDelete(obj, ar)
+		}
+		return h.finish(ar, start, warnings, err)
+	}
+
+	// CONNECT requests (e.g. pod exec/attach) have no object to validate;
+	// allow them, but still record the decision like every other operation.
+	return h.finish(ar, start, nil, nil)
+}
+
+// finish builds the Response for a completed validation attempt, and along
+// the way records Prometheus metrics, emits a structured log entry, and
+// forwards an AuditEvent to h.auditSink if one is configured. err is the
+// error returned by the validator (nil means allowed); warnings are
+// propagated regardless of the allow/deny outcome.
+func (h *validatingHandler) finish(ar AdmissionRequest, start time.Time, warnings []string, err error) Response {
+	allowed := err == nil
+	gvk := ar.Kind
+	operation := string(ar.Operation)
+	validatorName := fmt.Sprintf("%T", h.validator)
+	latency := time.Since(start).Seconds()
+
+	requestLatency.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, operation, validatorName).Observe(latency)
+	requestsTotal.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, operation, validatorName, strconv.FormatBool(allowed)).Inc()
+
+	logValues := []interface{}{
+		"group", gvk.Group, "version", gvk.Version, "kind", gvk.Kind,
+		"namespace", ar.Namespace, "name", ar.Name, "operation", operation,
+		"user", ar.UserInfo.Username, "allowed", allowed,
+	}
+
+	var resp Response
+	if allowed {
+		log.V(1).Info("admission request allowed", logValues...)
+		resp = allowedWithWarnings(warnings)
+	} else {
+		deniedTotal.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, operation, validatorName).Inc()
+		log.Info("admission request denied", append(logValues, "reason", err.Error())...)
+		resp = deniedWithWarnings(err, warnings)
+	}
+
+	if h.auditSink != nil {
+		event := AuditEvent{
+			GVK:       schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+			Operation: operation,
+			Namespace: ar.Namespace,
+			Name:      ar.Name,
+			UserInfo:  ar.UserInfo,
+			Allowed:   allowed,
+			Warnings:  warnings,
+		}
+		if !allowed {
+			event.Reason = err.Error()
+		}
+		h.auditSink.Audit(event)
+	}
+
+	return resp
+}
+
+// errored builds an Errored Response for a request that failed before
+// reaching the validator at all, e.g. an object decode failure. It records
+// the same Prometheus metrics and AuditEvent that finish records for a
+// validator-level denial, and logs the error at error level, so every
+// admission decision this handler makes is observed, regardless of where it
+// was decided.
+func (h *validatingHandler) errored(ar AdmissionRequest, start time.Time, code int32, err error) Response {
+	gvk := ar.Kind
+	operation := string(ar.Operation)
+	validatorName := fmt.Sprintf("%T", h.validator)
+	latency := time.Since(start).Seconds()
+
+	requestLatency.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, operation, validatorName).Observe(latency)
+	requestsTotal.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, operation, validatorName, strconv.FormatBool(false)).Inc()
+	deniedTotal.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, operation, validatorName).Inc()
+
+	log.Error(err, "admission request errored",
+		"group", gvk.Group, "version", gvk.Version, "kind", gvk.Kind,
+		"namespace", ar.Namespace, "name", ar.Name, "operation", operation,
+		"user", ar.UserInfo.Username)
+
+	if h.auditSink != nil {
+		h.auditSink.Audit(AuditEvent{
+			GVK:       schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+			Operation: operation,
+			Namespace: ar.Namespace,
+			Name:      ar.Name,
+			UserInfo:  ar.UserInfo,
+			Allowed:   false,
+			Reason:    err.Error(),
+		})
+	}
+
+	return Errored(code, err)
+}
+
+// allowedWithWarnings builds an Allowed response carrying the given
+// non-fatal warnings, if any. Response.Warnings requires k8s.io/api >=
+// v0.19.0 (Kubernetes 1.19+ apiserver); callers on an older pinned version
+// won't have this field, and clusters older than 1.19 silently drop it.
+func allowedWithWarnings(warnings []string) Response {
+	resp := Allowed("")
+	resp.Warnings = warnings
+	return resp
+}
+
+// deniedWithWarnings builds a Denied response for err, carrying the given
+// non-fatal warnings alongside it. See validationResponseFromError for how
+// err is turned into the response's denial reason.
+func deniedWithWarnings(err error, warnings []string) Response {
+	resp := validationResponseFromError(err)
+	resp.Warnings = warnings
+	return resp
+}
+
+// fieldErrorAggregate is implemented by the error returned from calling
+// ToAggregate on a field.ErrorList. It lets validationResponseFromError
+// recover the individual field.Error values without depending on
+// k8s.io/apimachinery/pkg/util/errors directly.
+type fieldErrorAggregate interface {
+	Errors() []error
+}
+
+// fieldErrorListFrom recovers the field.ErrorList underlying a
+// fieldErrorAggregate anywhere in err's Unwrap chain, or returns nil if none
+// is found or it doesn't carry exclusively *field.Error values.
+func fieldErrorListFrom(err error) field.ErrorList {
+	var agg fieldErrorAggregate
+	if !errors.As(err, &agg) {
+		return nil
+	}
+	var list field.ErrorList
+	for _, e := range agg.Errors() {
+		fe, ok := e.(*field.Error)
+		if !ok {
+			return nil
+		}
+		list = append(list, fe)
+	}
+	return list
+}
+
+// validationResponseFromError converts a validation error into a Denied
+// Response. If err is (or wraps) an *apierrors.StatusError, or is the
+// aggregate returned by field.ErrorList.ToAggregate, the response's Result
+// carries the corresponding structured `status.details.causes` so callers
+// can tell which field(s) failed validation; otherwise the error's message
+// is used as the denial reason.
+func validationResponseFromError(err error) Response {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) {
+		list := fieldErrorListFrom(err)
+		if list == nil {
 			return Denied(err.Error())
 		}
+		// The GVK of the object under validation isn't threaded through to
+		// here, so the kind is left blank; clients can rely on the
+		// request's GVK for context.
+		statusErr = apierrors.NewInvalid(schema.GroupKind{}, "", list)
 	}
 
-	return Allowed("")
+	resp := Denied(statusErr.Status().Message)
+	resp.Result = &statusErr.ErrStatus
+	return resp
 }
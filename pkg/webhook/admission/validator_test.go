@@ -0,0 +1,264 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestValidationResponseFromErrorPlainMessage(t *testing.T) {
+	resp := validationResponseFromError(errors.New("nope"))
+	if resp.Result == nil || resp.Result.Details != nil || resp.Result.Message != "nope" {
+		t.Fatalf("expected a flat Result with no structured details for a plain error, got %+v", resp.Result)
+	}
+	if resp.Allowed {
+		t.Fatalf("expected denial")
+	}
+}
+
+func TestValidationResponseFromErrorStatusError(t *testing.T) {
+	statusErr := apierrors.NewBadRequest("bad request")
+	resp := validationResponseFromError(statusErr)
+	if resp.Result == nil {
+		t.Fatalf("expected a structured Result for a *apierrors.StatusError")
+	}
+	if resp.Result.Message != statusErr.Status().Message {
+		t.Fatalf("Result.Message = %q, want %q", resp.Result.Message, statusErr.Status().Message)
+	}
+}
+
+func TestValidationResponseFromErrorFieldErrorListAggregate(t *testing.T) {
+	list := field.ErrorList{
+		field.Required(field.NewPath("spec", "name"), "name is required"),
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be >= 0"),
+	}
+
+	resp := validationResponseFromError(list.ToAggregate())
+	if resp.Result == nil {
+		t.Fatalf("expected a structured Result for a field.ErrorList aggregate")
+	}
+	if len(resp.Result.Details.Causes) != len(list) {
+		t.Fatalf("got %d causes, want %d", len(resp.Result.Details.Causes), len(list))
+	}
+}
+
+func TestValidationResponseFromErrorWrappedStatusError(t *testing.T) {
+	statusErr := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "p", errors.New("denied"))
+	wrapped := fmt.Errorf("validating pod: %w", statusErr)
+
+	resp := validationResponseFromError(wrapped)
+	if resp.Result == nil {
+		t.Fatalf("expected errors.As to unwrap the *apierrors.StatusError")
+	}
+}
+
+type fakeValidator struct {
+	runtime.Object
+	createErr error
+	updateErr error
+	deleteErr error
+}
+
+func (f *fakeValidator) DeepCopyObject() runtime.Object { return &fakeValidator{} }
+func (f *fakeValidator) ValidateCreate() error          { return f.createErr }
+func (f *fakeValidator) ValidateUpdate(runtime.Object) error {
+	return f.updateErr
+}
+func (f *fakeValidator) ValidateDelete() error { return f.deleteErr }
+
+func TestValidatorWrapperDelegates(t *testing.T) {
+	wantErr := errors.New("denied")
+	obj := &fakeValidator{createErr: wantErr}
+	v := NewValidatorWrapper(obj)
+	if err := v.ValidateCreate(obj, AdmissionRequest{}); err != wantErr {
+		t.Fatalf("ValidateCreate() = %v, want %v", err, wantErr)
+	}
+}
+
+type fakeValidatorWithWarnings struct {
+	fakeValidator
+	warnings []string
+}
+
+func (f *fakeValidatorWithWarnings) DeepCopyObject() runtime.Object {
+	return &fakeValidatorWithWarnings{}
+}
+func (f *fakeValidatorWithWarnings) ValidateCreate() ([]string, error) {
+	return f.warnings, f.createErr
+}
+func (f *fakeValidatorWithWarnings) ValidateUpdate(runtime.Object) ([]string, error) {
+	return f.warnings, f.updateErr
+}
+func (f *fakeValidatorWithWarnings) ValidateDelete() ([]string, error) {
+	return f.warnings, f.deleteErr
+}
+
+func TestValidatorWrapperWithWarningsDelegatesToWarningsValidator(t *testing.T) {
+	obj := &fakeValidatorWithWarnings{warnings: []string{"deprecated field"}}
+	v := NewValidatorWrapperWithWarnings(obj)
+	warnings, err := v.ValidateCreate(obj, AdmissionRequest{})
+	if err != nil {
+		t.Fatalf("ValidateCreate() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0] != "deprecated field" {
+		t.Fatalf("ValidateCreate() warnings = %v", warnings)
+	}
+}
+
+func TestValidatorWrapperWithWarningsFallsBackToPlainValidator(t *testing.T) {
+	v := NewValidatorWrapperWithWarnings(&fakeValidator{})
+	obj := v.DeepCopyObject()
+	warnings, err := v.ValidateCreate(obj, AdmissionRequest{})
+	if err != nil || warnings != nil {
+		t.Fatalf("ValidateCreate() = (%v, %v), want (nil, nil)", warnings, err)
+	}
+}
+
+// decodableObject is a minimal runtime.Object the decoder can unmarshal into.
+type decodableObject struct {
+	metav1.TypeMeta
+}
+
+func (o *decodableObject) DeepCopyObject() runtime.Object { return &decodableObject{} }
+
+// dryRunSpyValidator implements both MetaValidator and DryRunAwareValidator,
+// recording which of the two a given call landed on.
+type dryRunSpyValidator struct {
+	calledDryRun bool
+	calledPlain  bool
+}
+
+func (v *dryRunSpyValidator) DeepCopyObject() runtime.Object { return &decodableObject{} }
+func (v *dryRunSpyValidator) ValidateCreate(runtime.Object, AdmissionRequest) error {
+	v.calledPlain = true
+	return nil
+}
+func (v *dryRunSpyValidator) ValidateUpdate(_, _ runtime.Object, _ AdmissionRequest) error {
+	v.calledPlain = true
+	return nil
+}
+func (v *dryRunSpyValidator) ValidateDelete(runtime.Object, AdmissionRequest) error {
+	v.calledPlain = true
+	return nil
+}
+func (v *dryRunSpyValidator) ValidateCreateDryRun(runtime.Object, AdmissionRequest) error {
+	v.calledDryRun = true
+	return nil
+}
+func (v *dryRunSpyValidator) ValidateUpdateDryRun(_, _ runtime.Object, _ AdmissionRequest) error {
+	v.calledDryRun = true
+	return nil
+}
+func (v *dryRunSpyValidator) ValidateDeleteDryRun(runtime.Object, AdmissionRequest) error {
+	v.calledDryRun = true
+	return nil
+}
+
+var _ MetaValidator = &dryRunSpyValidator{}
+var _ DryRunAwareValidator = &dryRunSpyValidator{}
+
+func newCreateRequest(dryRun *bool) Request {
+	return Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			DryRun:    dryRun,
+			Object:    runtime.RawExtension{Raw: []byte("{}")},
+		},
+	}
+}
+
+func TestHandleRoutesDryRunRequestsToDryRunMethods(t *testing.T) {
+	v := &dryRunSpyValidator{}
+	h := newValidatingHandler(v)
+	h.decoder = NewDecoder(nil)
+
+	h.Handle(context.Background(), newCreateRequest(boolPtr(true)))
+
+	if !v.calledDryRun || v.calledPlain {
+		t.Fatalf("dry-run request: calledDryRun=%v calledPlain=%v, want calledDryRun=true calledPlain=false", v.calledDryRun, v.calledPlain)
+	}
+}
+
+func TestHandleRoutesNormalRequestsToPlainMethods(t *testing.T) {
+	v := &dryRunSpyValidator{}
+	h := newValidatingHandler(v)
+	h.decoder = NewDecoder(nil)
+
+	h.Handle(context.Background(), newCreateRequest(nil))
+
+	if v.calledDryRun || !v.calledPlain {
+		t.Fatalf("normal request: calledDryRun=%v calledPlain=%v, want calledDryRun=false calledPlain=true", v.calledDryRun, v.calledPlain)
+	}
+}
+
+// clientSpyValidator is a MetaValidator that also records the client.Client
+// it's injected with.
+type clientSpyValidator struct {
+	injected client.Client
+}
+
+func (v *clientSpyValidator) DeepCopyObject() runtime.Object { return &decodableObject{} }
+func (v *clientSpyValidator) ValidateCreate(runtime.Object, AdmissionRequest) error {
+	return nil
+}
+func (v *clientSpyValidator) ValidateUpdate(_, _ runtime.Object, _ AdmissionRequest) error {
+	return nil
+}
+func (v *clientSpyValidator) ValidateDelete(runtime.Object, AdmissionRequest) error {
+	return nil
+}
+func (v *clientSpyValidator) InjectClient(c client.Client) error {
+	v.injected = c
+	return nil
+}
+
+var _ MetaValidator = &clientSpyValidator{}
+var _ ClientInjector = &clientSpyValidator{}
+
+func TestWithClientInjectsClientIntoValidator(t *testing.T) {
+	v := &clientSpyValidator{}
+	c := fakeClient{}
+
+	wh := ValidatingWebhookFor(v, WithClient(c))
+
+	h, ok := wh.Handler.(*validatingHandler)
+	if !ok {
+		t.Fatalf("wh.Handler is %T, want *validatingHandler", wh.Handler)
+	}
+	if h.validator.(*clientSpyValidator).injected != client.Client(c) {
+		t.Fatalf("InjectClient was not forwarded to the wrapped validator")
+	}
+}
+
+// fakeClient satisfies client.Client without implementing every method, by
+// embedding the nil interface: only identity (for the test's == comparison
+// of the injected client) is exercised in this test.
+type fakeClient struct {
+	client.Client
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Request is the input to an admission Handler. It is always the
+// admission.k8s.io/v1 wire representation of the incoming AdmissionRequest:
+// ServeHTTP converts an admission.k8s.io/v1beta1 request to this shape
+// before a Handler ever sees it, so Handler implementations only deal with
+// one representation regardless of which version the caller spoke.
+type Request struct {
+	admissionv1.AdmissionRequest
+}
+
+// Response is the result of handling a Request. ServeHTTP translates it back
+// into whichever AdmissionReview apiVersion the incoming request used.
+type Response struct {
+	admissionv1.AdmissionResponse
+}
+
+// Handler handles an admission request and returns a response.
+type Handler interface {
+	Handle(ctx context.Context, req Request) Response
+}
+
+// HandlerFunc adapts a function into a Handler.
+type HandlerFunc func(context.Context, Request) Response
+
+// Handle calls f(ctx, req).
+func (f HandlerFunc) Handle(ctx context.Context, req Request) Response {
+	return f(ctx, req)
+}
+
+// Webhook represents each individual webhook.
+type Webhook struct {
+	// Handler handles the admission request once ServeHTTP has decoded it.
+	Handler Handler
+}
+
+// Handle processes a Request and returns a Response, carrying the request's
+// UID through to the response as the admission.k8s.io wire format requires.
+func (wh *Webhook) Handle(ctx context.Context, req Request) Response {
+	resp := wh.Handler.Handle(ctx, req)
+	resp.UID = req.UID
+	return resp
+}
+
+// DecoderInjector is implemented by handlers that need a *Decoder to turn a
+// request's raw object bytes into a runtime.Object. Webhook.InjectDecoder
+// forwards the decoder it is given to a Handler implementing this interface.
+type DecoderInjector interface {
+	InjectDecoder(d *Decoder) error
+}
+
+// InjectDecoder forwards d to wh.Handler if it implements DecoderInjector.
+func (wh *Webhook) InjectDecoder(d *Decoder) error {
+	if injector, ok := wh.Handler.(DecoderInjector); ok {
+		return injector.InjectDecoder(d)
+	}
+	return nil
+}
+
+// Allowed constructs a Response indicating the request is allowed, for the
+// given reason.
+func Allowed(reason string) Response {
+	return ValidationResponse(true, reason)
+}
+
+// Denied constructs a Response indicating the request is denied, for the
+// given reason.
+func Denied(reason string) Response {
+	return ValidationResponse(false, reason)
+}
+
+// ValidationResponse returns a Response for allowed/denied, with reason set
+// as the Result's Reason and Message whenever it is non-empty.
+func ValidationResponse(allowed bool, reason string) Response {
+	code := http.StatusForbidden
+	if allowed {
+		code = http.StatusOK
+	}
+	resp := Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: allowed,
+			Result:  &metav1.Status{Code: int32(code)},
+		},
+	}
+	if len(reason) > 0 {
+		resp.Result.Reason = metav1.StatusReason(reason)
+		resp.Result.Message = reason
+	}
+	return resp
+}
+
+// Errored creates a new Response for an error occurring while processing the
+// request, e.g. a failure to decode the incoming object.
+func Errored(code int32, err error) Response {
+	return Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Code:    code,
+				Message: err.Error(),
+			},
+		},
+	}
+}